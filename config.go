@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ExplicitChoice controls how SearchAndBuy handles a search result that
+// has both an explicit and a clean variant available.
+type ExplicitChoice string
+
+const (
+	ExplicitChoiceBuy          ExplicitChoice = "buy"
+	ExplicitChoiceSkip         ExplicitChoice = "skip"
+	ExplicitChoiceReplaceClean ExplicitChoice = "replace-with-clean"
+)
+
+// QualityTier selects which MP3 purchase tier to buy when Amazon offers
+// more than one for a track (e.g. standard definition vs HD vs UHD).
+type QualityTier string
+
+const (
+	QualityTierSD  QualityTier = "SD"
+	QualityTierHD  QualityTier = "HD"
+	QualityTierUHD QualityTier = "UHD"
+)
+
+// Config holds every setting AmazonMusicBuyer needs. It is loaded from
+// config.yaml, then overridden by environment variables, then by CLI
+// flags - each later source wins over the ones before it.
+type Config struct {
+	Email      string `yaml:"email"`
+	Password   string `yaml:"password"`
+	CookieFile string `yaml:"cookie-file,omitempty"`
+
+	// Storefront picks which Amazon TLD to shop on (us, uk, de, jp).
+	Storefront string `yaml:"storefront"`
+
+	SongFileFormat    string `yaml:"song-file-format"`
+	AlbumFolderFormat string `yaml:"album-folder-format"`
+
+	ExplicitChoice ExplicitChoice `yaml:"explicit-choice"`
+	Quality        QualityTier    `yaml:"quality"`
+
+	SaveLRCFile bool      `yaml:"save-lrc-file"`
+	LRCFormat   LRCFormat `yaml:"lrc-format"`
+	EmbedCover  bool      `yaml:"embed-cover"`
+	MaxPrice    float64   `yaml:"max-price"`
+
+	LedgerFile string `yaml:"ledger-file,omitempty"`
+}
+
+// CLIOverrides carries flag values that take precedence over both the
+// YAML config file and the environment when set explicitly on the
+// command line.
+type CLIOverrides struct {
+	Email      string
+	Password   string
+	CookieFile string
+	Storefront string
+}
+
+// storefrontTLDs maps a storefront code to the Amazon TLD it shops on.
+var storefrontTLDs = map[string]string{
+	"us": "com",
+	"uk": "co.uk",
+	"de": "de",
+	"jp": "co.jp",
+}
+
+// AmazonDomain returns the www.amazon.<tld> domain for the configured
+// storefront.
+func (c Config) AmazonDomain() string {
+	return "www.amazon." + storefrontTLDs[c.Storefront]
+}
+
+// AmazonMusicDomain returns the music.amazon.<tld> domain for the
+// configured storefront.
+func (c Config) AmazonMusicDomain() string {
+	return "music.amazon." + storefrontTLDs[c.Storefront]
+}
+
+func defaultConfig() Config {
+	return Config{
+		Storefront:        "us",
+		SongFileFormat:    "{{.TrackNumber}} {{.Title}}",
+		AlbumFolderFormat: "{{.Artist}}/{{.Album}}",
+		ExplicitChoice:    ExplicitChoiceBuy,
+		Quality:           QualityTierHD,
+		LRCFormat:         LRCFormatLRC,
+	}
+}
+
+// loadConfig merges settings from the YAML config file, environment
+// variables, and CLI flag overrides, in that order, then validates the
+// resolved configuration.
+func loadConfig(configFile string, overrides CLIOverrides) (Config, error) {
+	config := defaultConfig()
+
+	if configFile != "" {
+		data, err := os.ReadFile(configFile)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return config, fmt.Errorf("failed to read config file: %w", err)
+			}
+		} else if err := yaml.Unmarshal(data, &config); err != nil {
+			return config, fmt.Errorf("failed to parse config file: %w", err)
+		}
+	}
+
+	if email := os.Getenv("AMAZON_EMAIL"); email != "" {
+		config.Email = email
+	}
+	if password := os.Getenv("AMAZON_PASSWORD"); password != "" {
+		config.Password = password
+	}
+	if cookieFile := os.Getenv("AMAZON_COOKIE_FILE"); cookieFile != "" {
+		config.CookieFile = cookieFile
+	}
+	if storefront := os.Getenv("AMAZON_STOREFRONT"); storefront != "" {
+		config.Storefront = storefront
+	}
+
+	if overrides.Email != "" {
+		config.Email = overrides.Email
+	}
+	if overrides.Password != "" {
+		config.Password = overrides.Password
+	}
+	if overrides.CookieFile != "" {
+		config.CookieFile = overrides.CookieFile
+	}
+	if overrides.Storefront != "" {
+		config.Storefront = overrides.Storefront
+	}
+
+	if config.CookieFile == "" {
+		config.CookieFile = "amazon_cookies.json"
+	}
+	if config.LedgerFile == "" {
+		config.LedgerFile = "purchase_ledger.json"
+	}
+
+	if err := config.validate(); err != nil {
+		return config, err
+	}
+
+	return config, nil
+}
+
+func (c Config) validate() error {
+	if _, ok := storefrontTLDs[c.Storefront]; !ok {
+		return fmt.Errorf("unsupported storefront %q (expected one of us, uk, de, jp)", c.Storefront)
+	}
+
+	switch c.ExplicitChoice {
+	case ExplicitChoiceBuy, ExplicitChoiceSkip, ExplicitChoiceReplaceClean:
+	default:
+		return fmt.Errorf("unsupported explicit-choice %q", c.ExplicitChoice)
+	}
+
+	switch c.Quality {
+	case QualityTierSD, QualityTierHD, QualityTierUHD:
+	default:
+		return fmt.Errorf("unsupported quality %q (expected SD, HD, or UHD)", c.Quality)
+	}
+
+	switch c.LRCFormat {
+	case "", LRCFormatLRC, LRCFormatTTML, LRCFormatBoth:
+	default:
+		return fmt.Errorf("unsupported lrc-format %q (expected lrc, ttml, or both)", c.LRCFormat)
+	}
+
+	if c.Email == "" || c.Password == "" {
+		return fmt.Errorf("email and password must be provided via config file, environment variables, or flags")
+	}
+
+	return nil
+}
+
+// printConfig renders the resolved configuration as YAML for the
+// -print-config flag, redacting the account password.
+func printConfig(config Config) (string, error) {
+	redacted := config
+	if redacted.Password != "" {
+		redacted.Password = "********"
+	}
+
+	data, err := yaml.Marshal(redacted)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	return string(data), nil
+}
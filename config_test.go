@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigMergePrecedence(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	configYAML := "email: file@example.com\npassword: filepass\nstorefront: uk\n"
+	if err := os.WriteFile(configPath, []byte(configYAML), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	t.Setenv("AMAZON_EMAIL", "env@example.com")
+	t.Setenv("AMAZON_PASSWORD", "")
+	t.Setenv("AMAZON_COOKIE_FILE", "")
+	t.Setenv("AMAZON_STOREFRONT", "")
+
+	// Env overrides the file for email, but leaves password/storefront
+	// from the file in place since the other env vars are unset.
+	config, err := loadConfig(configPath, CLIOverrides{})
+	if err != nil {
+		t.Fatalf("loadConfig returned error: %v", err)
+	}
+	if config.Email != "env@example.com" {
+		t.Errorf("Email = %q, want env override %q", config.Email, "env@example.com")
+	}
+	if config.Password != "filepass" {
+		t.Errorf("Password = %q, want file value %q", config.Password, "filepass")
+	}
+	if config.Storefront != "uk" {
+		t.Errorf("Storefront = %q, want file value %q", config.Storefront, "uk")
+	}
+
+	// CLI flags override both the file and the environment.
+	config, err = loadConfig(configPath, CLIOverrides{Email: "cli@example.com", Storefront: "de"})
+	if err != nil {
+		t.Fatalf("loadConfig returned error: %v", err)
+	}
+	if config.Email != "cli@example.com" {
+		t.Errorf("Email = %q, want CLI override %q", config.Email, "cli@example.com")
+	}
+	if config.Storefront != "de" {
+		t.Errorf("Storefront = %q, want CLI override %q", config.Storefront, "de")
+	}
+}
+
+func TestLoadConfigMissingCredentials(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("storefront: us\n"), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	t.Setenv("AMAZON_EMAIL", "")
+	t.Setenv("AMAZON_PASSWORD", "")
+
+	if _, err := loadConfig(configPath, CLIOverrides{}); err == nil {
+		t.Error("loadConfig expected error for missing email/password, got nil")
+	}
+}
+
+func TestLoadConfigUnsupportedStorefront(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	yaml := "email: a@example.com\npassword: pw\nstorefront: zz\n"
+	if err := os.WriteFile(configPath, []byte(yaml), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	if _, err := loadConfig(configPath, CLIOverrides{}); err == nil {
+		t.Error("loadConfig expected error for unsupported storefront, got nil")
+	}
+}
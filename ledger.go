@@ -0,0 +1,171 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrAlreadyOwned is returned by Buy when Amazon reports that the
+// account already owns the track, instead of a generic purchase error.
+var ErrAlreadyOwned = errors.New("track is already owned")
+
+// PurchaseStatus is the outcome recorded for a ledger entry.
+type PurchaseStatus string
+
+const (
+	StatusPurchased    PurchaseStatus = "purchased"
+	StatusUnavailable  PurchaseStatus = "unavailable"
+	StatusFailed       PurchaseStatus = "failed"
+	StatusSkippedOwned PurchaseStatus = "skipped_owned"
+)
+
+// PurchaseRecord is one ledger entry, keyed by a stable hash of the item
+// it describes.
+type PurchaseRecord struct {
+	Status    PurchaseStatus `json:"status"`
+	ASIN      string         `json:"asin,omitempty"`
+	Price     string         `json:"price,omitempty"`
+	Timestamp time.Time      `json:"timestamp"`
+	Attempts  int            `json:"attempts"`
+	LastError string         `json:"last_error,omitempty"`
+}
+
+// PurchaseLedger tracks the outcome of every (artist, song, album,
+// storefront) this tool has attempted to buy, so re-running a CSV or URL
+// skips items that already succeeded. It is backed by a JSON file and
+// written atomically (write-temp-then-rename).
+type PurchaseLedger struct {
+	Path    string
+	records map[string]PurchaseRecord
+}
+
+// LoadLedger reads the ledger file at path, or returns an empty ledger if
+// it does not yet exist.
+func LoadLedger(path string) (*PurchaseLedger, error) {
+	ledger := &PurchaseLedger{Path: path, records: make(map[string]PurchaseRecord)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ledger, nil
+		}
+		return nil, fmt.Errorf("failed to read ledger file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &ledger.records); err != nil {
+		return nil, fmt.Errorf("failed to parse ledger file: %w", err)
+	}
+
+	return ledger, nil
+}
+
+// ledgerKey returns a stable key for (artist, song, album, storefront),
+// used so the same track always hashes to the same ledger entry
+// regardless of case differences in the CSV. Whitespace is not
+// normalised here.
+func ledgerKey(item MusicItem, storefront string) string {
+	parts := strings.ToLower(strings.Join([]string{item.Artist, item.Song, item.Album, storefront}, "|"))
+	sum := sha256.Sum256([]byte(parts))
+	return hex.EncodeToString(sum[:])
+}
+
+// Lookup returns the recorded outcome for item, if any.
+func (l *PurchaseLedger) Lookup(item MusicItem, storefront string) (PurchaseRecord, bool) {
+	record, ok := l.records[ledgerKey(item, storefront)]
+	return record, ok
+}
+
+// Skip reports whether item should be skipped because it was already
+// purchased or the account already owns it.
+func (l *PurchaseLedger) Skip(item MusicItem, storefront string) bool {
+	record, ok := l.Lookup(item, storefront)
+	if !ok {
+		return false
+	}
+	return record.Status == StatusPurchased || record.Status == StatusSkippedOwned
+}
+
+// Record updates the ledger entry for item and atomically persists the
+// whole ledger to disk.
+func (l *PurchaseLedger) Record(item MusicItem, storefront string, status PurchaseStatus, asin, price string, recordErr error) error {
+	key := ledgerKey(item, storefront)
+	record := l.records[key]
+
+	record.Status = status
+	record.Timestamp = time.Now()
+	record.Attempts++
+	if asin != "" {
+		record.ASIN = asin
+	}
+	if price != "" {
+		record.Price = price
+	}
+	if recordErr != nil {
+		record.LastError = recordErr.Error()
+	} else {
+		record.LastError = ""
+	}
+
+	l.records[key] = record
+	return l.save()
+}
+
+// IsFailed reports whether item's recorded status is "failed", for use
+// by -retry-failed to select which rows to re-run.
+func (l *PurchaseLedger) IsFailed(item MusicItem, storefront string) bool {
+	record, ok := l.Lookup(item, storefront)
+	return ok && record.Status == StatusFailed
+}
+
+func (l *PurchaseLedger) save() error {
+	data, err := json.MarshalIndent(l.records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal ledger: %w", err)
+	}
+
+	dir := filepath.Dir(l.Path)
+	tmp, err := os.CreateTemp(dir, ".purchase_ledger-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp ledger file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write temp ledger file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to close temp ledger file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, l.Path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to rename temp ledger file into place: %w", err)
+	}
+
+	return nil
+}
+
+// backoffDelay returns the exponential backoff delay for the given
+// zero-based attempt number, base 2 seconds, uncapped by the caller
+// (callers pass successive attempts for -retry-failed retries).
+func backoffDelay(attempt int) time.Duration {
+	return time.Duration(1<<uint(attempt)) * 2 * time.Second
+}
+
+// ownedSelectors match the DOM state Amazon renders in place of a buy
+// button when the account already owns a track.
+var ownedSelectors = []string{
+	"[aria-label*='You already own']",
+	"[data-testid='already-owned']",
+	"button:has-text('Play now')",
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLedgerKeyStable(t *testing.T) {
+	item := MusicItem{Artist: "The Beatles", Song: "Let It Be", Album: "Let It Be"}
+
+	a := ledgerKey(item, "us")
+	b := ledgerKey(item, "us")
+	if a != b {
+		t.Errorf("ledgerKey not stable across calls: %q != %q", a, b)
+	}
+
+	// Case shouldn't change the key.
+	sameItem := MusicItem{Artist: "the beatles", Song: "let it be", Album: "let it be"}
+	if got := ledgerKey(sameItem, "us"); got != a {
+		t.Errorf("ledgerKey(%+v) = %q, want %q (case-insensitive)", sameItem, got, a)
+	}
+
+	// A different storefront must hash differently.
+	if got := ledgerKey(item, "uk"); got == a {
+		t.Errorf("ledgerKey should differ by storefront, got same key %q for us and uk", got)
+	}
+}
+
+func TestPurchaseLedgerSkip(t *testing.T) {
+	ledger := &PurchaseLedger{Path: filepath.Join(t.TempDir(), "ledger.json"), records: make(map[string]PurchaseRecord)}
+	item := MusicItem{Artist: "Artist", Song: "Song"}
+
+	if ledger.Skip(item, "us") {
+		t.Fatal("Skip should be false for an item with no ledger entry")
+	}
+
+	if err := ledger.Record(item, "us", StatusFailed, "", "", nil); err != nil {
+		t.Fatalf("Record returned error: %v", err)
+	}
+	if ledger.Skip(item, "us") {
+		t.Error("Skip should be false for a failed item")
+	}
+	if !ledger.IsFailed(item, "us") {
+		t.Error("IsFailed should be true after recording a failed status")
+	}
+
+	if err := ledger.Record(item, "us", StatusPurchased, "B000ASIN", "$1.29", nil); err != nil {
+		t.Fatalf("Record returned error: %v", err)
+	}
+	if !ledger.Skip(item, "us") {
+		t.Error("Skip should be true once purchased")
+	}
+	if ledger.IsFailed(item, "us") {
+		t.Error("IsFailed should be false once purchased")
+	}
+}
+
+func TestBackoffDelayGrows(t *testing.T) {
+	prev := backoffDelay(0)
+	for attempt := 1; attempt < 5; attempt++ {
+		next := backoffDelay(attempt)
+		if next <= prev {
+			t.Errorf("backoffDelay(%d) = %v, want greater than backoffDelay(%d) = %v", attempt, next, attempt-1, prev)
+		}
+		prev = next
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/sirupsen/logrus"
+)
+
+// LRCFormat selects which lyrics file format(s) to save.
+type LRCFormat string
+
+const (
+	LRCFormatLRC  LRCFormat = "lrc"
+	LRCFormatTTML LRCFormat = "ttml"
+	LRCFormatBoth LRCFormat = "both"
+)
+
+// lyricsLine is one timed line from Amazon Music's lyrics endpoint.
+type lyricsLine struct {
+	StartTimeMs int    `json:"startTimeMs"`
+	Text        string `json:"text"`
+}
+
+// lyricsResponse is the subset of Amazon's lyrics endpoint response this
+// downloader understands: either a timed line array, or a plain-text
+// fallback when no synchronized lyrics are available.
+type lyricsResponse struct {
+	Lines     []lyricsLine `json:"lines"`
+	PlainText string       `json:"plainText"`
+}
+
+// LyricsFetcher fetches and saves lyrics for purchased tracks, reusing
+// the authenticated rod session so it sees the same cookies as the
+// purchase flow.
+type LyricsFetcher struct {
+	page   *rod.Page
+	logger *logrus.Logger
+	config Config
+}
+
+// NewLyricsFetcher builds a LyricsFetcher sharing amb's authenticated
+// page and configuration.
+func (amb *AmazonMusicBuyer) NewLyricsFetcher() *LyricsFetcher {
+	return &LyricsFetcher{
+		page:   amb.page,
+		logger: amb.logger,
+		config: amb.config,
+	}
+}
+
+// FetchAndSave fetches lyrics for asin and writes them next to outputPath
+// (same directory and base name, with a .lrc/.ttml extension) according to
+// the configured lrc-format. It logs at info level and returns nil
+// without error when the lyrics endpoint has nothing for this track.
+func (lf *LyricsFetcher) FetchAndSave(asin string, outputPath string) error {
+	resp, err := lf.fetch(asin)
+	if err != nil {
+		lf.logger.WithError(err).WithField("asin", asin).Info("No lyrics available for track")
+		return nil
+	}
+
+	base := strings.TrimSuffix(outputPath, filepath.Ext(outputPath))
+
+	if err := os.MkdirAll(filepath.Dir(base), 0755); err != nil {
+		return fmt.Errorf("failed to create lyrics output directory: %w", err)
+	}
+
+	format := lf.config.lrcFormat()
+	if format == LRCFormatLRC || format == LRCFormatBoth {
+		if err := os.WriteFile(base+".lrc", []byte(toLRC(resp)), 0644); err != nil {
+			return fmt.Errorf("failed to write LRC file: %w", err)
+		}
+	}
+	if format == LRCFormatTTML || format == LRCFormatBoth {
+		if err := os.WriteFile(base+".ttml", []byte(toTTML(resp)), 0644); err != nil {
+			return fmt.Errorf("failed to write TTML file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// fetch requests the lyrics JSON for asin from Amazon Music's internal
+// lyrics endpoint using the page's existing authenticated session
+// cookies (including media-user-token).
+func (lf *LyricsFetcher) fetch(asin string) (*lyricsResponse, error) {
+	url := fmt.Sprintf("https://%s/api/lyrics/v1/%s", lf.config.AmazonMusicDomain(), asin)
+
+	result := lf.page.MustEval(fmt.Sprintf(`() => fetch(%q, {credentials: "include"}).then(r => r.ok ? r.text() : null)`, url))
+	if result.Str() == "" {
+		return nil, fmt.Errorf("no lyrics response for %s", asin)
+	}
+
+	var resp lyricsResponse
+	if err := json.Unmarshal([]byte(result.Str()), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse lyrics response: %w", err)
+	}
+
+	if len(resp.Lines) == 0 && resp.PlainText == "" {
+		return nil, fmt.Errorf("empty lyrics response for %s", asin)
+	}
+
+	return &resp, nil
+}
+
+// toLRC renders a lyrics response as an LRC file: timed lines sorted by
+// start time, or the plain-text fallback (returned unchanged, without
+// timestamps) when no synchronized lines were returned.
+func toLRC(resp *lyricsResponse) string {
+	if len(resp.Lines) == 0 {
+		return resp.PlainText
+	}
+
+	lines := make([]lyricsLine, len(resp.Lines))
+	copy(lines, resp.Lines)
+	sort.Slice(lines, func(i, j int) bool { return lines[i].StartTimeMs < lines[j].StartTimeMs })
+
+	var b strings.Builder
+	for _, line := range lines {
+		minutes := line.StartTimeMs / 60000
+		seconds := float64(line.StartTimeMs%60000) / 1000
+		fmt.Fprintf(&b, "[%02d:%05.2f]%s\n", minutes, seconds, line.Text)
+	}
+	return b.String()
+}
+
+// toTTML renders a lyrics response as a minimal TTML document, for users
+// who want the original timed-text markup rather than LRC.
+func toTTML(resp *lyricsResponse) string {
+	var b strings.Builder
+	b.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	b.WriteString("<tt xmlns=\"http://www.w3.org/ns/ttml\">\n  <body>\n    <div>\n")
+	for _, line := range resp.Lines {
+		begin := line.StartTimeMs
+		fmt.Fprintf(&b, "      <p begin=\"%dms\">%s</p>\n", begin, line.Text)
+	}
+	b.WriteString("    </div>\n  </body>\n</tt>\n")
+	return b.String()
+}
+
+func (c Config) lrcFormat() LRCFormat {
+	switch c.LRCFormat {
+	case LRCFormatTTML, LRCFormatBoth:
+		return c.LRCFormat
+	default:
+		return LRCFormatLRC
+	}
+}
+
+// outputPath builds the destination path for a purchased track's
+// companion files (lyrics, embedded cover, etc.), following the
+// configured album-folder-format/song-file-format layout.
+func outputPath(config Config, candidate TrackCandidate) string {
+	folder := strings.NewReplacer(
+		"{{.Artist}}", candidate.Artist,
+		"{{.Album}}", candidate.Album,
+	).Replace(config.AlbumFolderFormat)
+
+	file := strings.NewReplacer(
+		"{{.TrackNumber}}", "",
+		"{{.Title}}", candidate.Title,
+	).Replace(config.SongFileFormat)
+
+	return strings.TrimSpace(filepath.Join(folder, strings.TrimSpace(file)))
+}
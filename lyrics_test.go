@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToLRCSortsAndFormats(t *testing.T) {
+	resp := &lyricsResponse{
+		Lines: []lyricsLine{
+			{StartTimeMs: 65000, Text: "second line"},
+			{StartTimeMs: 1500, Text: "first line"},
+		},
+	}
+
+	got := toLRC(resp)
+	want := "[00:01.50]first line\n[01:05.00]second line\n"
+	if got != want {
+		t.Errorf("toLRC() = %q, want %q", got, want)
+	}
+}
+
+func TestToLRCPlainTextFallback(t *testing.T) {
+	resp := &lyricsResponse{PlainText: "no timing info"}
+	if got := toLRC(resp); got != "no timing info" {
+		t.Errorf("toLRC() = %q, want plain text fallback", got)
+	}
+}
+
+func TestToTTMLIncludesTimedLines(t *testing.T) {
+	resp := &lyricsResponse{
+		Lines: []lyricsLine{{StartTimeMs: 1000, Text: "hello"}},
+	}
+
+	got := toTTML(resp)
+	for _, want := range []string{"<tt", "begin=\"1000ms\"", "hello", "</tt>"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("toTTML() = %q, missing expected markup %q", got, want)
+		}
+	}
+}
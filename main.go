@@ -1,8 +1,10 @@
 package main
 
 import (
+	"bufio"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -24,72 +26,30 @@ type MusicItem struct {
 	Album  string `csv:"album,omitempty"`
 }
 
-type Config struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
-	CookieFile string `json:"cookie_file,omitempty"`
-}
-
 type AmazonMusicBuyer struct {
-	browser *rod.Browser
-	page    *rod.Page
-	logger  *logrus.Logger
-	config  Config
+	browser  *rod.Browser
+	page     *rod.Page
+	logger   *logrus.Logger
+	config   Config
 	headless bool
 }
 
-func NewAmazonMusicBuyer(headless bool, configFile string) (*AmazonMusicBuyer, error) {
+func NewAmazonMusicBuyer(headless bool, configFile string, overrides CLIOverrides) (*AmazonMusicBuyer, error) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.InfoLevel)
-	
-	config, err := loadConfig(configFile)
+
+	config, err := loadConfig(configFile, overrides)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
-	
-	if config.CookieFile == "" {
-		config.CookieFile = "amazon_cookies.json"
-	}
-	
+
 	return &AmazonMusicBuyer{
-		logger: logger,
-		config: config,
+		logger:   logger,
+		config:   config,
 		headless: headless,
 	}, nil
 }
 
-func loadConfig(configFile string) (Config, error) {
-	var config Config
-	
-	// Try config file first
-	if configFile != "" {
-		data, err := ioutil.ReadFile(configFile)
-		if err != nil {
-			return config, fmt.Errorf("failed to read config file: %w", err)
-		}
-		if err := json.Unmarshal(data, &config); err != nil {
-			return config, fmt.Errorf("failed to parse config file: %w", err)
-		}
-	}
-	
-	// Override with environment variables if set
-	if email := os.Getenv("AMAZON_EMAIL"); email != "" {
-		config.Email = email
-	}
-	if password := os.Getenv("AMAZON_PASSWORD"); password != "" {
-		config.Password = password
-	}
-	if cookieFile := os.Getenv("AMAZON_COOKIE_FILE"); cookieFile != "" {
-		config.CookieFile = cookieFile
-	}
-	
-	if config.Email == "" || config.Password == "" {
-		return config, fmt.Errorf("email and password must be provided via config file or environment variables")
-	}
-	
-	return config, nil
-}
-
 func (amb *AmazonMusicBuyer) Initialize() error {
 	l := launcher.New()
 	if amb.headless {
@@ -97,10 +57,10 @@ func (amb *AmazonMusicBuyer) Initialize() error {
 	} else {
 		l = l.Headless(false)
 	}
-	
+
 	// Set user agent to avoid detection
 	l = l.Set("user-agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-	
+
 	url, err := l.Launch()
 	if err != nil {
 		return fmt.Errorf("failed to launch browser: %w", err)
@@ -112,10 +72,10 @@ func (amb *AmazonMusicBuyer) Initialize() error {
 	}
 
 	amb.page = amb.browser.MustPage()
-	
+
 	// Set viewport for consistency
 	amb.page.MustSetViewport(1920, 1080, 1, false)
-	
+
 	return nil
 }
 
@@ -130,16 +90,16 @@ func (amb *AmazonMusicBuyer) Close() {
 
 func (amb *AmazonMusicBuyer) SaveCookies() error {
 	cookies := amb.page.MustCookies()
-	
+
 	data, err := json.Marshal(cookies)
 	if err != nil {
 		return fmt.Errorf("failed to marshal cookies: %w", err)
 	}
-	
+
 	if err := ioutil.WriteFile(amb.config.CookieFile, data, 0600); err != nil {
 		return fmt.Errorf("failed to save cookies: %w", err)
 	}
-	
+
 	amb.logger.Info("Cookies saved successfully")
 	return nil
 }
@@ -148,17 +108,17 @@ func (amb *AmazonMusicBuyer) LoadCookies() error {
 	if _, err := os.Stat(amb.config.CookieFile); os.IsNotExist(err) {
 		return fmt.Errorf("cookie file does not exist")
 	}
-	
+
 	data, err := ioutil.ReadFile(amb.config.CookieFile)
 	if err != nil {
 		return fmt.Errorf("failed to read cookie file: %w", err)
 	}
-	
+
 	var cookies []*proto.NetworkCookie
 	if err := json.Unmarshal(data, &cookies); err != nil {
 		return fmt.Errorf("failed to unmarshal cookies: %w", err)
 	}
-	
+
 	// Convert NetworkCookie to NetworkCookieParam for setting
 	for _, cookie := range cookies {
 		amb.page.MustSetCookies(&proto.NetworkCookieParam{
@@ -172,19 +132,19 @@ func (amb *AmazonMusicBuyer) LoadCookies() error {
 			SameSite: cookie.SameSite,
 		})
 	}
-	
+
 	amb.logger.Info("Cookies loaded successfully")
 	return nil
 }
 
 func (amb *AmazonMusicBuyer) Login() error {
 	amb.logger.Info("Attempting to login to Amazon Music...")
-	
+
 	// First try to load existing cookies
 	if err := amb.LoadCookies(); err == nil {
-		amb.page.MustNavigate("https://music.amazon.com/")
+		amb.page.MustNavigate(fmt.Sprintf("https://%s/", amb.config.AmazonMusicDomain()))
 		amb.page.MustWaitLoad()
-		
+
 		// Check if cookies are still valid
 		if amb.isLoggedIn() {
 			amb.logger.Info("Successfully logged in using saved cookies")
@@ -192,61 +152,61 @@ func (amb *AmazonMusicBuyer) Login() error {
 		}
 		amb.logger.Info("Saved cookies are expired, performing fresh login")
 	}
-	
+
 	// Perform fresh login
-	amb.page.MustNavigate("https://www.amazon.com/")
+	amb.page.MustNavigate(fmt.Sprintf("https://%s/", amb.config.AmazonDomain()))
 	amb.page.MustWaitLoad()
-	
+
 	// Click sign in button
 	signInLink := amb.page.MustElement("#nav-link-accountList")
 	signInLink.MustClick()
 	amb.page.MustWaitLoad()
-	
+
 	// Enter email
 	emailField := amb.page.MustElement("#ap_email")
 	emailField.MustInput(amb.config.Email)
-	
+
 	continueBtn := amb.page.MustElement("#continue")
 	continueBtn.MustClick()
 	amb.page.MustWaitLoad()
-	
+
 	// Enter password
 	time.Sleep(1 * time.Second) // Small delay to avoid detection
 	passwordField := amb.page.MustElement("#ap_password")
 	passwordField.MustInput(amb.config.Password)
-	
+
 	signInBtn := amb.page.MustElement("#signInSubmit")
 	signInBtn.MustClick()
 	amb.page.MustWaitLoad()
-	
+
 	// Check for CAPTCHA
 	if amb.page.MustHas("#auth-captcha-image") {
 		amb.logger.Error("CAPTCHA detected - manual intervention required")
 		amb.logger.Info("Please solve the CAPTCHA manually in the browser window...")
-		
+
 		if amb.headless {
 			return fmt.Errorf("CAPTCHA detected in headless mode - cannot proceed")
 		}
-		
+
 		// Wait for user to solve CAPTCHA
 		for amb.page.MustHas("#auth-captcha-image") {
 			time.Sleep(2 * time.Second)
 		}
 	}
-	
+
 	// Check for 2FA
 	if amb.page.MustHas("#auth-mfa-otpcode") {
 		amb.logger.Info("2FA detected - checking for OTP code...")
-		
+
 		otpCode := os.Getenv("AMAZON_OTP")
 		if otpCode == "" && amb.headless {
 			return fmt.Errorf("2FA required but no OTP code provided in headless mode")
 		}
-		
+
 		if otpCode != "" {
 			otpField := amb.page.MustElement("#auth-mfa-otpcode")
 			otpField.MustInput(otpCode)
-			
+
 			submitBtn := amb.page.MustElement("#auth-signin-button")
 			submitBtn.MustClick()
 		} else {
@@ -256,143 +216,153 @@ func (amb *AmazonMusicBuyer) Login() error {
 			}
 		}
 	}
-	
+
 	amb.page.MustWaitLoad()
-	
+
 	// Navigate to Amazon Music
-	amb.page.MustNavigate("https://music.amazon.com/")
+	amb.page.MustNavigate(fmt.Sprintf("https://%s/", amb.config.AmazonMusicDomain()))
 	amb.page.MustWaitLoad()
-	
+
 	if !amb.isLoggedIn() {
 		return fmt.Errorf("login failed - unable to access Amazon Music")
 	}
-	
+
 	// Save cookies for future use
 	if err := amb.SaveCookies(); err != nil {
 		amb.logger.WithError(err).Warn("Failed to save cookies")
 	}
-	
+
 	amb.logger.Info("Successfully logged in to Amazon Music")
 	return nil
 }
 
 func (amb *AmazonMusicBuyer) isLoggedIn() bool {
 	// Check multiple indicators of being logged in
-	return amb.page.MustHas("#nav-link-accountList-nav-line-1") || 
-		   amb.page.MustHas("[data-testid='user-menu']") ||
-		   amb.page.MustHas("#glow-ingress-line1")
+	return amb.page.MustHas("#nav-link-accountList-nav-line-1") ||
+		amb.page.MustHas("[data-testid='user-menu']") ||
+		amb.page.MustHas("#glow-ingress-line1")
 }
 
+// SearchAndBuy searches for item and purchases the first matching
+// candidate. It is a thin convenience wrapper around Search and Buy for
+// the automated (non-interactive) flow.
 func (amb *AmazonMusicBuyer) SearchAndBuy(item MusicItem) error {
-	amb.logger.WithFields(logrus.Fields{
-		"artist": item.Artist,
-		"song":   item.Song,
-		"album":  item.Album,
-	}).Info("Searching for music item...")
-	
-	// Navigate to Amazon Digital Music Store
-	amb.page.MustNavigate("https://www.amazon.com/music/unlimited")
-	amb.page.MustWaitLoad()
-	time.Sleep(2 * time.Second)
-	
-	// Build search query
-	searchQuery := fmt.Sprintf("%s %s", item.Artist, item.Song)
-	if item.Album != "" {
-		searchQuery += " " + item.Album
-	}
-	
-	// Try to find and use the search box
-	searchBox := amb.page.MustElement("#twotabsearchtextbox")
-	searchBox.MustClick()
-	searchBox.MustSelectAllText()
-	searchBox.MustInput(searchQuery)
-	
-	searchButton := amb.page.MustElement("#nav-search-submit-button")
-	searchButton.MustClick()
-	amb.page.MustWaitLoad()
-	
-	// Wait for search results
-	time.Sleep(3 * time.Second)
-	
-	// Look for digital music purchase options
-	// Try to find MP3 purchase buttons or links
-	buySelectors := []string{
-		"[aria-label*='Buy MP3']",
-		"[aria-label*='Buy Song']",
-		"button:has-text('Buy MP3')",
-		"a:has-text('Buy MP3')",
-		".a-button-buy-mp3",
-	}
-	
-	var found bool
-	for _, selector := range buySelectors {
-		if amb.page.MustHas(selector) {
-			element := amb.page.MustElement(selector)
-			element.MustClick()
-			found = true
-			break
-		}
+	candidates, err := amb.Search(item)
+	if err != nil {
+		return err
+	}
+
+	if len(candidates) == 0 {
+		return fmt.Errorf("no purchase options found for: %s %s", item.Artist, item.Song)
+	}
+
+	return amb.Buy(candidates[0])
+}
+
+// ProcessCSVSelect buys every row of a CSV file via the interactive (or
+// headless preselected) -select flow, consulting ledger the same way as
+// ProcessCSVConcurrent.
+func (amb *AmazonMusicBuyer) ProcessCSVSelect(filename string, ledger *PurchaseLedger, opts ProcessOptions, r *bufio.Reader, preselected map[string]string) error {
+	return amb.processCSV(filename, ledger, opts, func(item MusicItem) error {
+		return amb.SearchAndBuySelect(item, r, preselected)
+	})
+}
+
+// ProcessOptions controls resume/retry behavior shared by
+// ProcessCSVConcurrent and ProcessCSVSelect.
+type ProcessOptions struct {
+	RetryFailed bool
+	DryRun      bool
+}
+
+func (amb *AmazonMusicBuyer) processCSV(filename string, ledger *PurchaseLedger, opts ProcessOptions, buy func(MusicItem) error) error {
+	items, err := parseCSVItems(filename)
+	if err != nil {
+		return err
 	}
-	
-	if !found {
-		amb.logger.WithField("query", searchQuery).Warn("No purchase options found")
-		return fmt.Errorf("no purchase options found for: %s", searchQuery)
-	}
-	
-	// Handle purchase confirmation
-	time.Sleep(2 * time.Second)
-	
-	// Check for "Buy now" or confirmation button
-	confirmSelectors := []string{
-		"#buy-now-button",
-		"[name='submit.buy-now']",
-		"input[aria-labelledby*='buy-now']",
-		"#a-autoid-0-announce",
-	}
-	
-	for _, selector := range confirmSelectors {
-		if amb.page.MustHas(selector) {
-			element := amb.page.MustElement(selector)
-			element.MustClick()
-			break
+
+	successCount := 0
+	failCount := 0
+	skippedCount := 0
+
+	for _, item := range items {
+		if opts.RetryFailed {
+			if !ledger.IsFailed(item, amb.config.Storefront) {
+				continue
+			}
+		} else if ledger.Skip(item, amb.config.Storefront) {
+			amb.logger.WithField("item", item).Info("Skipping item already purchased or owned")
+			skippedCount++
+			continue
 		}
+
+		if opts.DryRun {
+			record, _ := ledger.Lookup(item, amb.config.Storefront)
+			amb.logger.WithFields(logrus.Fields{"item": item, "would_attempt": true, "prior_status": record.Status}).Info("Dry run: would attempt purchase")
+			continue
+		}
+
+		attempt := 0
+		if record, ok := ledger.Lookup(item, amb.config.Storefront); ok && opts.RetryFailed {
+			attempt = record.Attempts
+			time.Sleep(backoffDelay(attempt))
+		}
+
+		buyErr := buy(item)
+		switch {
+		case buyErr == nil:
+			_ = ledger.Record(item, amb.config.Storefront, StatusPurchased, "", "", nil)
+			successCount++
+		case errors.Is(buyErr, ErrAlreadyOwned):
+			_ = ledger.Record(item, amb.config.Storefront, StatusSkippedOwned, "", "", nil)
+			skippedCount++
+		default:
+			amb.logger.WithError(buyErr).WithField("item", item).Error("Failed to process item")
+			_ = ledger.Record(item, amb.config.Storefront, StatusFailed, "", "", buyErr)
+			failCount++
+		}
+
+		// Add delay between purchases to avoid rate limiting
+		time.Sleep(5 * time.Second)
 	}
-	
-	amb.logger.Info("Purchase initiated successfully")
-	time.Sleep(3 * time.Second)
-	
+
+	amb.logger.WithFields(logrus.Fields{
+		"success": successCount,
+		"failed":  failCount,
+		"skipped": skippedCount,
+		"total":   successCount + failCount + skippedCount,
+	}).Info("Finished processing CSV")
+
 	return nil
 }
 
-func (amb *AmazonMusicBuyer) ProcessCSV(filename string) error {
+// parseCSVItems reads a CSV file of artist,song,album rows into
+// MusicItems, skipping a header row if present.
+func parseCSVItems(filename string) ([]MusicItem, error) {
 	file, err := os.Open(filename)
 	if err != nil {
-		return fmt.Errorf("failed to open CSV file: %w", err)
+		return nil, fmt.Errorf("failed to open CSV file: %w", err)
 	}
 	defer file.Close()
 
 	reader := csv.NewReader(file)
 	records, err := reader.ReadAll()
 	if err != nil {
-		return fmt.Errorf("failed to read CSV: %w", err)
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
 	}
 
 	if len(records) == 0 {
-		return fmt.Errorf("CSV file is empty")
+		return nil, fmt.Errorf("CSV file is empty")
 	}
 
-	// Skip header row if it exists
 	start := 0
 	if records[0][0] == "artist" || records[0][0] == "Artist" {
 		start = 1
 	}
 
-	successCount := 0
-	failCount := 0
-
+	var items []MusicItem
 	for i := start; i < len(records); i++ {
 		if len(records[i]) < 2 {
-			amb.logger.WithField("row", i).Warn("Skipping row with insufficient data")
 			continue
 		}
 
@@ -400,88 +370,104 @@ func (amb *AmazonMusicBuyer) ProcessCSV(filename string) error {
 			Artist: strings.TrimSpace(records[i][0]),
 			Song:   strings.TrimSpace(records[i][1]),
 		}
-		
 		if len(records[i]) > 2 {
 			item.Album = strings.TrimSpace(records[i][2])
 		}
-
-		if err := amb.SearchAndBuy(item); err != nil {
-			amb.logger.WithError(err).WithField("item", item).Error("Failed to process item")
-			failCount++
-			continue
-		}
-		
-		successCount++
-		// Add delay between purchases to avoid rate limiting
-		time.Sleep(5 * time.Second)
+		items = append(items, item)
 	}
 
-	amb.logger.WithFields(logrus.Fields{
-		"success": successCount,
-		"failed":  failCount,
-		"total":   successCount + failCount,
-	}).Info("Finished processing CSV")
-
-	return nil
+	return items, nil
 }
 
 func parseSongString(songStr string) (MusicItem, error) {
 	parts := strings.Split(songStr, " - ")
-	
+
 	if len(parts) < 2 {
 		return MusicItem{}, fmt.Errorf("invalid song format. Use 'Artist - Song' or 'Artist - Song - Album'")
 	}
-	
+
 	item := MusicItem{
 		Artist: strings.TrimSpace(parts[0]),
 		Song:   strings.TrimSpace(parts[1]),
 	}
-	
+
 	if len(parts) >= 3 {
 		item.Album = strings.TrimSpace(parts[2])
 	}
-	
+
 	if item.Artist == "" || item.Song == "" {
 		return MusicItem{}, fmt.Errorf("artist and song cannot be empty")
 	}
-	
+
 	return item, nil
 }
 
 func main() {
 	csvFile := flag.String("csv", "", "Path to CSV file containing music list")
 	song := flag.String("song", "", "Single song to buy (format: 'Artist - Song' or 'Artist - Song - Album')")
-	configFile := flag.String("config", "config.json", "Path to configuration file")
+	musicURL := flag.String("url", "", "Amazon Music album, playlist, or artist URL to buy every track from")
+	configFile := flag.String("config", "config.yaml", "Path to configuration file")
 	headless := flag.Bool("headless", true, "Run browser in headless mode (default: true)")
 	priceOnly := flag.Bool("price", false, "Analyze pricing only (no login required)")
 	outputJSON := flag.String("output-json", "", "Save pricing report as JSON")
 	outputCSV := flag.String("output-csv", "", "Save pricing report as CSV")
+	email := flag.String("email", "", "Amazon account email (overrides config file and environment)")
+	password := flag.String("password", "", "Amazon account password (overrides config file and environment)")
+	cookieFile := flag.String("cookie-file", "", "Path to cookie file (overrides config file and environment)")
+	storefront := flag.String("storefront", "", "Amazon storefront to use: us, uk, de, or jp (overrides config file)")
+	printConfigFlag := flag.Bool("print-config", false, "Print the resolved configuration and exit")
+	selectMode := flag.Bool("select", false, "Interactively choose among matching search results instead of buying the first match")
+	inputFile := flag.String("input", "", "Preselected query=ASIN mapping file (required for -select in -headless mode)")
+	retryFailed := flag.Bool("retry-failed", false, "Only re-run CSV rows whose ledger status is 'failed'")
+	dryRun := flag.Bool("dry-run", false, "Walk the ledger and CSV and report what would happen, without buying anything")
+	concurrency := flag.Int("concurrency", 2, "Number of concurrent workers for -csv processing")
+	reqsPerSec := flag.Float64("rate-limit", 1.0, "Maximum requests per second to Amazon across all workers")
 	flag.Parse()
 
+	overrides := CLIOverrides{
+		Email:      *email,
+		Password:   *password,
+		CookieFile: *cookieFile,
+		Storefront: *storefront,
+	}
+
+	if *printConfigFlag {
+		config, err := loadConfig(*configFile, overrides)
+		if err != nil {
+			log.Fatal("Failed to load config:", err)
+		}
+		rendered, err := printConfig(config)
+		if err != nil {
+			log.Fatal("Failed to render config:", err)
+		}
+		fmt.Print(rendered)
+		return
+	}
+
 	// Price analysis mode (no login required) - delegates to Playwright service
 	if *priceOnly {
 		if *csvFile == "" {
 			fmt.Println("Error: -csv flag is required for pricing analysis")
 			os.Exit(1)
 		}
-		
+
 		// Build command for Playwright pricing service
 		cmd := []string{"npm", "run", "dev", "--", "analyze", "-i", *csvFile}
-		
+
 		if !*headless {
 			cmd = append(cmd, "--visible")
 		}
-		
+
 		if *outputJSON != "" {
 			cmd = append(cmd, "-o", *outputJSON)
 		}
-		
+
 		if *outputCSV != "" {
 			cmd = append(cmd, "-c", *outputCSV)
 		}
-		
+
 		fmt.Println("Starting Playwright-based pricing analysis (no login required)...")
-		
+
 		// Check if pricing service dependencies are installed
 		if _, err := os.Stat("pricing-service/node_modules"); os.IsNotExist(err) {
 			fmt.Println("Installing pricing service dependencies...")
@@ -491,27 +477,28 @@ func main() {
 				log.Fatal("Failed to install pricing service dependencies:", err)
 			}
 		}
-		
+
 		// Run the pricing service
 		pricingCmd := exec.Command(cmd[0], cmd[1:]...)
 		pricingCmd.Dir = "pricing-service"
 		pricingCmd.Stdout = os.Stdout
 		pricingCmd.Stderr = os.Stderr
-		
+
 		if err := pricingCmd.Run(); err != nil {
 			log.Fatal("Pricing analysis failed:", err)
 		}
-		
+
 		return
 	}
 
 	// Purchase mode (login required)
-	if *csvFile == "" && *song == "" {
+	if *csvFile == "" && *song == "" && *musicURL == "" {
 		fmt.Println("Usage:")
 		fmt.Println("")
 		fmt.Println("PURCHASE MODE (requires login):")
 		fmt.Println("  amazon-music-buyer -csv <path-to-csv-file>")
 		fmt.Println("  amazon-music-buyer -song \"Artist - Song\"")
+		fmt.Println("  amazon-music-buyer -url <amazon-music-album/playlist/artist-url>")
 		fmt.Println("")
 		fmt.Println("PRICING ANALYSIS MODE (no login required):")
 		fmt.Println("  amazon-music-buyer -price -csv <path-to-csv-file>")
@@ -520,7 +507,15 @@ func main() {
 		fmt.Println("")
 		fmt.Println("Options:")
 		fmt.Println("  -price           Analyze pricing only (no login/purchase)")
-		fmt.Println("  -config <file>   Configuration file (default: config.json)")
+		fmt.Println("  -config <file>   Configuration file (default: config.yaml)")
+		fmt.Println("  -storefront      Amazon storefront: us, uk, de, jp (overrides config file)")
+		fmt.Println("  -print-config    Print the resolved configuration and exit")
+		fmt.Println("  -select          Interactively choose among matching search results")
+		fmt.Println("  -input <file>    Preselected query=ASIN file (required for -select in -headless mode)")
+		fmt.Println("  -retry-failed    Only re-run CSV rows whose ledger status is 'failed'")
+		fmt.Println("  -dry-run         Report what -csv would do without buying anything")
+		fmt.Println("  -concurrency     Number of concurrent workers for -csv processing (default: 2)")
+		fmt.Println("  -rate-limit      Maximum requests per second to Amazon (default: 1)")
 		fmt.Println("  -headless        Run in headless mode (default: true)")
 		fmt.Println("  -output-json     Save pricing report as JSON")
 		fmt.Println("  -output-csv      Save pricing report as CSV")
@@ -529,21 +524,52 @@ func main() {
 		fmt.Println("  AMAZON_EMAIL     Amazon account email")
 		fmt.Println("  AMAZON_PASSWORD  Amazon account password")
 		fmt.Println("  AMAZON_OTP       2FA OTP code (optional)")
+		fmt.Println("  AMAZON_STOREFRONT  Amazon storefront: us, uk, de, jp")
 		fmt.Println("")
 		fmt.Println("CSV format: artist,song,album (album is optional)")
 		os.Exit(1)
 	}
 
-	if *csvFile != "" && *song != "" {
-		fmt.Println("Error: Cannot use both -csv and -song flags simultaneously")
+	modesSelected := 0
+	for _, selected := range []bool{*csvFile != "", *song != "", *musicURL != ""} {
+		if selected {
+			modesSelected++
+		}
+	}
+	if modesSelected > 1 {
+		fmt.Println("Error: Cannot use more than one of -csv, -song, and -url simultaneously")
 		os.Exit(1)
 	}
 
-	buyer, err := NewAmazonMusicBuyer(*headless, *configFile)
+	if *selectMode && *musicURL != "" {
+		fmt.Println("Error: -select is not supported with -url")
+		os.Exit(1)
+	}
+
+	var preselected map[string]string
+	if *selectMode && *headless {
+		if *inputFile == "" {
+			fmt.Println("Error: -select in -headless mode requires -input pointing at a preselected query=ASIN file")
+			os.Exit(1)
+		}
+		var err error
+		preselected, err = loadPreselectedASINs(*inputFile)
+		if err != nil {
+			log.Fatal("Failed to load preselected input file:", err)
+		}
+	} else if *selectMode && *inputFile != "" {
+		var err error
+		preselected, err = loadPreselectedASINs(*inputFile)
+		if err != nil {
+			log.Fatal("Failed to load preselected input file:", err)
+		}
+	}
+
+	buyer, err := NewAmazonMusicBuyer(*headless, *configFile, overrides)
 	if err != nil {
 		log.Fatal("Failed to create buyer:", err)
 	}
-	
+
 	if err := buyer.Initialize(); err != nil {
 		log.Fatal("Failed to initialize browser:", err)
 	}
@@ -553,22 +579,47 @@ func main() {
 		log.Fatal("Failed to login:", err)
 	}
 
+	stdin := bufio.NewReader(os.Stdin)
+
 	if *song != "" {
 		item, err := parseSongString(*song)
 		if err != nil {
 			log.Fatal("Failed to parse song string:", err)
 		}
-		
-		if err := buyer.SearchAndBuy(item); err != nil {
+
+		if *selectMode {
+			err = buyer.SearchAndBuySelect(item, stdin, preselected)
+		} else {
+			err = buyer.SearchAndBuy(item)
+		}
+		if err != nil {
 			log.Fatal("Failed to buy song:", err)
 		}
-		
+
 		fmt.Printf("Successfully processed song: %s by %s\n", item.Song, item.Artist)
+	} else if *musicURL != "" {
+		if err := buyer.ProcessURL(*musicURL); err != nil {
+			log.Fatal("Failed to process URL:", err)
+		}
+
+		fmt.Println("Finished processing Amazon Music URL!")
 	} else {
-		if err := buyer.ProcessCSV(*csvFile); err != nil {
+		ledger, err := LoadLedger(buyer.config.LedgerFile)
+		if err != nil {
+			log.Fatal("Failed to load purchase ledger:", err)
+		}
+
+		opts := ProcessOptions{RetryFailed: *retryFailed, DryRun: *dryRun}
+
+		if *selectMode {
+			err = buyer.ProcessCSVSelect(*csvFile, ledger, opts, stdin, preselected)
+		} else {
+			err = buyer.ProcessCSVConcurrent(*csvFile, ledger, opts, *concurrency, *reqsPerSec)
+		}
+		if err != nil {
 			log.Fatal("Failed to process CSV:", err)
 		}
-		
+
 		fmt.Println("Finished processing CSV file!")
 	}
-}
\ No newline at end of file
+}
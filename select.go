@@ -0,0 +1,348 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/sirupsen/logrus"
+)
+
+// TrackCandidate is one matching MP3 result scraped from an Amazon search
+// results page, ready to be purchased via Buy.
+type TrackCandidate struct {
+	ASIN     string
+	Title    string
+	Artist   string
+	Album    string
+	Price    string
+	Duration string
+}
+
+// Search runs the Amazon Digital Music Store search for item and scrapes
+// every matching MP3 result into a list of candidates. It honors the
+// configured explicit-content preference by dropping explicit rows (when
+// ExplicitChoice is "skip") before returning.
+func (amb *AmazonMusicBuyer) Search(item MusicItem) (candidates []TrackCandidate, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("search failed for %s %s: %v", item.Artist, item.Song, r)
+		}
+	}()
+
+	amb.logger.WithFields(logrus.Fields{
+		"artist": item.Artist,
+		"song":   item.Song,
+		"album":  item.Album,
+	}).Info("Searching for music item...")
+
+	amb.page.MustNavigate(fmt.Sprintf("https://%s/music/unlimited", amb.config.AmazonDomain()))
+	amb.page.MustWaitLoad()
+	time.Sleep(2 * time.Second)
+
+	if throttled(amb.page) {
+		return nil, ErrThrottled
+	}
+
+	searchQuery := fmt.Sprintf("%s %s", item.Artist, item.Song)
+	if item.Album != "" {
+		searchQuery += " " + item.Album
+	}
+
+	searchBox := amb.page.MustElement("#twotabsearchtextbox")
+	searchBox.MustClick()
+	searchBox.MustSelectAllText()
+	searchBox.MustInput(searchQuery)
+
+	searchButton := amb.page.MustElement("#nav-search-submit-button")
+	searchButton.MustClick()
+	amb.page.MustWaitLoad()
+
+	time.Sleep(3 * time.Second)
+
+	if !amb.page.MustHas("[data-testid='search-result-row']") {
+		amb.logger.WithField("query", searchQuery).Warn("No purchase options found")
+		return nil, nil
+	}
+
+	for _, row := range amb.page.MustElements("[data-testid='search-result-row']") {
+		candidate := parseResultRow(row)
+
+		if amb.config.ExplicitChoice == ExplicitChoiceSkip && row.MustHas("[aria-label*='Explicit']") {
+			continue
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, nil
+}
+
+// Buy purchases a single candidate previously returned by Search. It
+// re-locates the candidate's row by ASIN on the page currently shown and
+// returns an error if that row is not there; it does not navigate back
+// to the search results.
+func (amb *AmazonMusicBuyer) Buy(candidate TrackCandidate) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("buy failed for %s: %v", candidate.ASIN, r)
+		}
+	}()
+
+	if throttled(amb.page) {
+		return ErrThrottled
+	}
+
+	selector := fmt.Sprintf("[data-testid='search-result-row'][data-asin='%s']", candidate.ASIN)
+	if !amb.page.MustHas(selector) {
+		return fmt.Errorf("candidate %s is no longer present on the search results page", candidate.ASIN)
+	}
+	row := amb.page.MustElement(selector)
+
+	if amb.config.ExplicitChoice == ExplicitChoiceReplaceClean && row.MustHas("[aria-label*='Explicit']") && row.MustHas("[aria-label*='Clean']") {
+		row.MustElement("[aria-label*='Clean']").MustClick()
+	}
+
+	if amb.config.Quality != QualityTierHD {
+		if sel := fmt.Sprintf("[aria-label*='%s']", amb.config.Quality); row.MustHas(sel) {
+			row.MustElement(sel).MustClick()
+		} else {
+			amb.logger.WithFields(logrus.Fields{
+				"asin":    candidate.ASIN,
+				"quality": amb.config.Quality,
+			}).Warn("Requested quality tier not offered for this track, buying default")
+		}
+	}
+
+	for _, sel := range ownedSelectors {
+		if row.MustHas(sel) {
+			amb.logger.WithField("asin", candidate.ASIN).Info("Track already owned, skipping purchase")
+			return ErrAlreadyOwned
+		}
+	}
+
+	if amb.config.MaxPrice > 0 {
+		if price, err := parsePrice(candidate.Price); err == nil && price > amb.config.MaxPrice {
+			amb.logger.WithFields(logrus.Fields{
+				"asin":      candidate.ASIN,
+				"price":     price,
+				"max_price": amb.config.MaxPrice,
+			}).Info("Skipping purchase: price exceeds max-price")
+			return fmt.Errorf("price %.2f exceeds configured max-price %.2f for %s", price, amb.config.MaxPrice, candidate.ASIN)
+		}
+	}
+
+	buySelectors := []string{
+		"[aria-label*='Buy MP3']",
+		"[aria-label*='Buy Song']",
+		"button:has-text('Buy MP3')",
+		"a:has-text('Buy MP3')",
+		".a-button-buy-mp3",
+	}
+
+	var found bool
+	for _, sel := range buySelectors {
+		if row.MustHas(sel) {
+			row.MustElement(sel).MustClick()
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		amb.logger.WithField("asin", candidate.ASIN).Warn("No purchase options found")
+		return fmt.Errorf("no purchase options found for: %s", candidate.ASIN)
+	}
+
+	time.Sleep(2 * time.Second)
+
+	confirmSelectors := []string{
+		"#buy-now-button",
+		"[name='submit.buy-now']",
+		"input[aria-labelledby*='buy-now']",
+		"#a-autoid-0-announce",
+	}
+
+	for _, sel := range confirmSelectors {
+		if amb.page.MustHas(sel) {
+			amb.page.MustElement(sel).MustClick()
+			break
+		}
+	}
+
+	amb.logger.Info("Purchase initiated successfully")
+	time.Sleep(3 * time.Second)
+
+	if amb.config.SaveLRCFile {
+		path := outputPath(amb.config, candidate)
+		if err := amb.NewLyricsFetcher().FetchAndSave(candidate.ASIN, path); err != nil {
+			amb.logger.WithError(err).WithField("asin", candidate.ASIN).Warn("Failed to fetch lyrics")
+		}
+	}
+
+	return nil
+}
+
+func parseResultRow(row *rod.Element) TrackCandidate {
+	attr := func(name string) string {
+		if v := row.MustAttribute(name); v != nil {
+			return *v
+		}
+		return ""
+	}
+
+	return TrackCandidate{
+		ASIN:     attr("data-asin"),
+		Title:    attr("data-title"),
+		Artist:   attr("data-artist"),
+		Album:    attr("data-album"),
+		Price:    attr("data-price"),
+		Duration: attr("data-duration"),
+	}
+}
+
+// parsePrice extracts a float dollar amount from a scraped price string
+// such as "$1.29", stripping any currency symbol.
+func parsePrice(price string) (float64, error) {
+	trimmed := strings.TrimFunc(price, func(r rune) bool {
+		return !(r >= '0' && r <= '9') && r != '.'
+	})
+	if trimmed == "" {
+		return 0, fmt.Errorf("no numeric price in %q", price)
+	}
+	return strconv.ParseFloat(trimmed, 64)
+}
+
+// SelectCandidates prints candidates numbered to stdout and reads a
+// selection (e.g. "1,3,5-7" or "all") from r, returning the chosen
+// candidates in list order.
+func SelectCandidates(candidates []TrackCandidate, r *bufio.Reader) ([]TrackCandidate, error) {
+	for i, c := range candidates {
+		fmt.Printf("%2d) %s - %s (%s) [%s, %s]\n", i+1, c.Artist, c.Title, c.Album, c.Price, c.Duration)
+	}
+	fmt.Print("Select tracks to buy (e.g. 1,3,5-7 or 'all'): ")
+
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read selection: %w", err)
+	}
+
+	return parseSelection(strings.TrimSpace(line), candidates)
+}
+
+func parseSelection(input string, candidates []TrackCandidate) ([]TrackCandidate, error) {
+	if strings.EqualFold(input, "all") {
+		return candidates, nil
+	}
+
+	indices := make(map[int]bool)
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		if dash := strings.Index(part, "-"); dash > 0 {
+			start, err := strconv.Atoi(strings.TrimSpace(part[:dash]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid selection range %q: %w", part, err)
+			}
+			end, err := strconv.Atoi(strings.TrimSpace(part[dash+1:]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid selection range %q: %w", part, err)
+			}
+			for i := start; i <= end; i++ {
+				indices[i] = true
+			}
+			continue
+		}
+
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selection %q: %w", part, err)
+		}
+		indices[n] = true
+	}
+
+	var selected []TrackCandidate
+	for i := 1; i <= len(candidates); i++ {
+		if indices[i] {
+			selected = append(selected, candidates[i-1])
+		}
+	}
+	return selected, nil
+}
+
+// loadPreselectedASINs reads a "query=ASIN" mapping file (one per line)
+// used by -select in headless mode, where there is no terminal to read
+// an interactive selection from.
+func loadPreselectedASINs(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read preselected input file: %w", err)
+	}
+
+	mapping := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid preselected input line %q: expected 'query=ASIN'", line)
+		}
+		mapping[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
+	return mapping, nil
+}
+
+// SearchAndBuySelect runs the interactive (or headless preselected)
+// -select flow for a single item: it scrapes candidates via Search, then
+// either prompts the user to choose or consults a preselected mapping,
+// and buys each chosen candidate.
+func (amb *AmazonMusicBuyer) SearchAndBuySelect(item MusicItem, r *bufio.Reader, preselected map[string]string) error {
+	candidates, err := amb.Search(item)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("no purchase options found for: %s %s", item.Artist, item.Song)
+	}
+
+	var chosen []TrackCandidate
+	if preselected != nil {
+		query := fmt.Sprintf("%s - %s", item.Artist, item.Song)
+		asin, ok := preselected[query]
+		if !ok {
+			return fmt.Errorf("no preselected ASIN found for %q in input file", query)
+		}
+		for _, c := range candidates {
+			if c.ASIN == asin {
+				chosen = append(chosen, c)
+				break
+			}
+		}
+		if len(chosen) == 0 {
+			return fmt.Errorf("preselected ASIN %s not found among search results for %q", asin, query)
+		}
+	} else {
+		chosen, err = SelectCandidates(candidates, r)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, c := range chosen {
+		if err := amb.Buy(c); err != nil {
+			amb.logger.WithError(err).WithField("asin", c.ASIN).Warn("Failed to buy selected candidate")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSelection(t *testing.T) {
+	candidates := []TrackCandidate{
+		{ASIN: "A1"}, {ASIN: "A2"}, {ASIN: "A3"}, {ASIN: "A4"}, {ASIN: "A5"},
+	}
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"all", []string{"A1", "A2", "A3", "A4", "A5"}},
+		{"1", []string{"A1"}},
+		{"1,3", []string{"A1", "A3"}},
+		{"2-4", []string{"A2", "A3", "A4"}},
+		{"1,3,5-7", []string{"A1", "A3", "A5"}},
+		{" 1 , 3 ", []string{"A1", "A3"}},
+	}
+
+	for _, tt := range tests {
+		selected, err := parseSelection(tt.input, candidates)
+		if err != nil {
+			t.Fatalf("parseSelection(%q) returned error: %v", tt.input, err)
+		}
+
+		var gotASINs []string
+		for _, c := range selected {
+			gotASINs = append(gotASINs, c.ASIN)
+		}
+		if !reflect.DeepEqual(gotASINs, tt.want) {
+			t.Errorf("parseSelection(%q) = %v, want %v", tt.input, gotASINs, tt.want)
+		}
+	}
+}
+
+func TestParseSelectionInvalid(t *testing.T) {
+	candidates := []TrackCandidate{{ASIN: "A1"}}
+
+	for _, input := range []string{"x", "1-", "1,x"} {
+		if _, err := parseSelection(input, candidates); err == nil {
+			t.Errorf("parseSelection(%q) expected error, got nil", input)
+		}
+	}
+}
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    float64
+		wantErr bool
+	}{
+		{"$1.29", 1.29, false},
+		{"12.99", 12.99, false},
+		{"$0.99", 0.99, false},
+		{"", 0, true},
+		{"free", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parsePrice(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parsePrice(%q) expected error, got nil", tt.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("parsePrice(%q) returned error: %v", tt.input, err)
+		}
+		if got != tt.want {
+			t.Errorf("parsePrice(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/sirupsen/logrus"
+)
+
+// URLKind identifies what a music.amazon.com URL points at.
+type URLKind int
+
+const (
+	URLKindUnknown URLKind = iota
+	URLKindAlbum
+	URLKindPlaylist
+	URLKindArtist
+)
+
+// urlTrack is a single row scraped from an album/playlist/artist track table.
+type urlTrack struct {
+	Artist string
+	Title  string
+	Album  string
+	Track  int
+	ASIN   string
+}
+
+// detectURLKind inspects the path of a music.amazon.com URL and reports
+// whether it points at an album, a playlist, or an artist page.
+func detectURLKind(rawURL string) URLKind {
+	idx := strings.Index(rawURL, "music.amazon.")
+	if idx == -1 {
+		return URLKindUnknown
+	}
+	path := rawURL[idx:]
+	if slash := strings.Index(path, "/"); slash != -1 {
+		path = path[slash:]
+	}
+	path = strings.TrimPrefix(path, "/")
+
+	switch {
+	case strings.HasPrefix(path, "albums/"):
+		return URLKindAlbum
+	case strings.HasPrefix(path, "playlists/"):
+		return URLKindPlaylist
+	case strings.HasPrefix(path, "artists/"):
+		return URLKindArtist
+	default:
+		return URLKindUnknown
+	}
+}
+
+// ProcessURL accepts a full Amazon Music URL (album, playlist, or artist),
+// enumerates every track it contains, and feeds each one through
+// SearchAndBuy. It logs a summary of the tracks found, purchased, and
+// skipped as unavailable; the returned error only reports failures to
+// recognize the URL or enumerate its tracks.
+func (amb *AmazonMusicBuyer) ProcessURL(url string) error {
+	kind := detectURLKind(url)
+	if kind == URLKindUnknown {
+		return fmt.Errorf("unrecognized Amazon Music URL: %s", url)
+	}
+
+	amb.logger.WithFields(logrus.Fields{
+		"url":  url,
+		"kind": urlKindName(kind),
+	}).Info("Navigating to Amazon Music URL...")
+
+	amb.page.MustNavigate(url)
+	amb.page.MustWaitLoad()
+	time.Sleep(2 * time.Second)
+
+	var tracks []urlTrack
+	var err error
+	switch kind {
+	case URLKindPlaylist:
+		tracks, err = amb.scrollAndScrapeTracks()
+	default:
+		tracks, err = amb.scrapeTrackTable()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to enumerate tracks: %w", err)
+	}
+
+	tracks = dedupeTracksByASIN(tracks)
+	amb.logger.WithField("count", len(tracks)).Info("Enumerated tracks from URL")
+
+	var purchased, unavailable int
+	for _, t := range tracks {
+		item := MusicItem{Artist: t.Artist, Song: t.Title, Album: t.Album}
+		if err := amb.SearchAndBuy(item); err != nil {
+			amb.logger.WithError(err).WithField("item", item).Warn("Track unavailable")
+			unavailable++
+			continue
+		}
+		purchased++
+	}
+
+	amb.logger.WithFields(logrus.Fields{
+		"found":       len(tracks),
+		"purchased":   purchased,
+		"unavailable": unavailable,
+	}).Info("Finished processing Amazon Music URL")
+
+	return nil
+}
+
+// scrapeTrackTable reads the static track list rendered on an album or
+// artist page.
+func (amb *AmazonMusicBuyer) scrapeTrackTable() ([]urlTrack, error) {
+	if !amb.page.MustHas("[data-testid='track-list']") {
+		return nil, fmt.Errorf("track list not found on page")
+	}
+
+	rows := amb.page.MustElements("[data-testid='track-row']")
+	tracks := make([]urlTrack, 0, len(rows))
+	for _, row := range rows {
+		tracks = append(tracks, parseTrackRow(row))
+	}
+	return tracks, nil
+}
+
+// scrollAndScrapeTracks pages through a playlist by scrolling / clicking
+// "Show more" until the number of rendered track rows stabilizes, then
+// scrapes the full set. Playlists are rendered incrementally rather than
+// via an offset query parameter, so this is the equivalent of the
+// chunked offset pagination used elsewhere.
+func (amb *AmazonMusicBuyer) scrollAndScrapeTracks() ([]urlTrack, error) {
+	if !amb.page.MustHas("[data-testid='track-list']") {
+		return nil, fmt.Errorf("track list not found on page")
+	}
+
+	const stableRoundsRequired = 3
+	stableRounds := 0
+	lastCount := -1
+
+	for stableRounds < stableRoundsRequired {
+		rows := amb.page.MustElements("[data-testid='track-row']")
+		count := len(rows)
+
+		if amb.page.MustHas("button:has-text('Show more')") {
+			amb.page.MustElement("button:has-text('Show more')").MustClick()
+		} else if len(rows) > 0 {
+			rows[len(rows)-1].MustScrollIntoView()
+		}
+
+		time.Sleep(1 * time.Second)
+
+		if count == lastCount {
+			stableRounds++
+		} else {
+			stableRounds = 0
+		}
+		lastCount = count
+	}
+
+	return amb.scrapeTrackTable()
+}
+
+func parseTrackRow(row *rod.Element) urlTrack {
+	attr := func(name string) string {
+		if v := row.MustAttribute(name); v != nil {
+			return *v
+		}
+		return ""
+	}
+
+	track := urlTrack{
+		Artist: attr("data-artist"),
+		Title:  attr("data-title"),
+		Album:  attr("data-album"),
+		ASIN:   attr("data-asin"),
+	}
+	if n, err := strconv.Atoi(attr("data-track-number")); err == nil {
+		track.Track = n
+	}
+	return track
+}
+
+func dedupeTracksByASIN(tracks []urlTrack) []urlTrack {
+	seen := make(map[string]bool, len(tracks))
+	out := make([]urlTrack, 0, len(tracks))
+	for _, t := range tracks {
+		if t.ASIN != "" {
+			if seen[t.ASIN] {
+				continue
+			}
+			seen[t.ASIN] = true
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+func urlKindName(kind URLKind) string {
+	switch kind {
+	case URLKindAlbum:
+		return "album"
+	case URLKindPlaylist:
+		return "playlist"
+	case URLKindArtist:
+		return "artist"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestDetectURLKind(t *testing.T) {
+	tests := []struct {
+		url  string
+		want URLKind
+	}{
+		{"https://music.amazon.com/albums/B000ASIN", URLKindAlbum},
+		{"https://music.amazon.co.uk/playlists/abc123", URLKindPlaylist},
+		{"https://music.amazon.de/artists/abc123/some-artist", URLKindArtist},
+		{"https://music.amazon.com/", URLKindUnknown},
+		{"https://www.amazon.com/music/unlimited", URLKindUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := detectURLKind(tt.url); got != tt.want {
+			t.Errorf("detectURLKind(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestDedupeTracksByASIN(t *testing.T) {
+	tracks := []urlTrack{
+		{ASIN: "A1", Title: "First"},
+		{ASIN: "A2", Title: "Second"},
+		{ASIN: "A1", Title: "First duplicate"},
+		{ASIN: "", Title: "No ASIN"},
+		{ASIN: "", Title: "Also no ASIN"},
+	}
+
+	got := dedupeTracksByASIN(tracks)
+	if len(got) != 4 {
+		t.Fatalf("dedupeTracksByASIN() returned %d tracks, want 4: %+v", len(got), got)
+	}
+	if got[0].ASIN != "A1" || got[0].Title != "First" {
+		t.Errorf("expected first A1 track to win, got %+v", got[0])
+	}
+}
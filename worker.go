@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/schollz/progressbar/v3"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+// throttleSelectors match the DOM state Amazon shows when a request has
+// been rate-limited or challenged as a bot: a CAPTCHA, the "are you a
+// robot" interstitial, or the 503 error page rendered in place of the
+// normal page.
+var throttleSelectors = []string{
+	"#captchacharacters",
+	"*:has-text('Sorry, we just need to make sure you')",
+	"*:has-text('503 Service Unavailable')",
+}
+
+// ErrThrottled is returned by Search and Buy when the page shows a
+// CAPTCHA, bot-check, or 503 interstitial instead of the normal Amazon
+// UI, so callers can back off and retry rather than failing the item
+// outright.
+var ErrThrottled = errors.New("amazon is throttling or challenging this request")
+
+const (
+	backoffBase        = 2 * time.Second
+	backoffCap         = 5 * time.Minute
+	maxThrottleRetries = 5
+)
+
+// RateLimiter wraps a single token-bucket limiter shared by all workers
+// so they stay under a configurable requests-per-second budget. It keeps
+// one bucket for every Amazon host together, not a separate bucket per
+// host.
+type RateLimiter struct {
+	limiter *rate.Limiter
+}
+
+// NewRateLimiter builds a RateLimiter allowing reqsPerSec sustained
+// requests per second, with a burst of one.
+func NewRateLimiter(reqsPerSec float64) *RateLimiter {
+	return &RateLimiter{limiter: rate.NewLimiter(rate.Limit(reqsPerSec), 1)}
+}
+
+// Wait blocks until the limiter permits another request.
+func (rl *RateLimiter) Wait() {
+	_ = rl.limiter.Wait(context.Background())
+}
+
+// workerJob is one CSV row queued for a worker, tracking its own
+// in-worker retry attempt for backoff purposes.
+type workerJob struct {
+	item    MusicItem
+	attempt int
+}
+
+// ProcessCSVConcurrent buys every row of a CSV file using a pool of
+// concurrency rod pages sharing the authenticated browser context.
+// Cookies are loaded once up front; SaveCookies is only ever called from
+// this coordinator goroutine, never from a worker, to avoid concurrent
+// writes to rod's cookie store.
+func (amb *AmazonMusicBuyer) ProcessCSVConcurrent(filename string, ledger *PurchaseLedger, opts ProcessOptions, concurrency int, reqsPerSec float64) error {
+	items, err := parseCSVItems(filename)
+	if err != nil {
+		return err
+	}
+
+	var pending []MusicItem
+	for _, item := range items {
+		if opts.RetryFailed {
+			if ledger.IsFailed(item, amb.config.Storefront) {
+				pending = append(pending, item)
+			}
+			continue
+		}
+		if ledger.Skip(item, amb.config.Storefront) {
+			continue
+		}
+		pending = append(pending, item)
+	}
+
+	if len(pending) == 0 {
+		amb.logger.Info("Nothing to do: every item is already purchased, owned, or not failed")
+		return nil
+	}
+
+	if opts.DryRun {
+		for _, item := range pending {
+			record, _ := ledger.Lookup(item, amb.config.Storefront)
+			amb.logger.WithFields(logrus.Fields{"item": item, "would_attempt": true, "prior_status": record.Status}).Info("Dry run: would attempt purchase")
+		}
+		return nil
+	}
+
+	// jobs is sized for the worst case where every pending item gets
+	// requeued up to maxThrottleRetries times, so a worker can always push
+	// a throttled item back without blocking. remaining tracks how many
+	// items (not attempts) are still outstanding so the coordinator knows
+	// when to close the channel.
+	jobs := make(chan workerJob, len(pending)*(maxThrottleRetries+1))
+	var remaining sync.WaitGroup
+	remaining.Add(len(pending))
+	for _, item := range pending {
+		jobs <- workerJob{item: item}
+	}
+
+	go func() {
+		remaining.Wait()
+		close(jobs)
+	}()
+
+	bar := progressbar.Default(int64(len(pending)), "buying tracks")
+	limiter := NewRateLimiter(reqsPerSec)
+
+	var (
+		mu                       sync.Mutex
+		successCount, failCount  int
+		skippedCount, retryCount int
+		cookiesDirty             bool
+	)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+
+			page := amb.browser.MustPage()
+			defer page.Close()
+			page.MustSetViewport(1920, 1080, 1, false)
+			amb.applyCookiesTo(page)
+
+			worker := &AmazonMusicBuyer{
+				browser:  amb.browser,
+				page:     page,
+				logger:   amb.logger,
+				config:   amb.config,
+				headless: amb.headless,
+			}
+
+			for job := range jobs {
+				limiter.Wait()
+
+				err := worker.SearchAndBuy(job.item)
+
+				if (errors.Is(err, ErrThrottled) || throttled(page)) && job.attempt < maxThrottleRetries {
+					delay := backoffWithJitter(job.attempt)
+					worker.logger.WithFields(logrus.Fields{
+						"worker":  workerID,
+						"item":    job.item,
+						"delay":   delay,
+						"attempt": job.attempt + 1,
+					}).Warn("Amazon throttling detected, backing off and requeuing")
+
+					mu.Lock()
+					retryCount++
+					mu.Unlock()
+
+					time.Sleep(delay)
+					jobs <- workerJob{item: job.item, attempt: job.attempt + 1}
+					continue
+				}
+
+				mu.Lock()
+				switch {
+				case err == nil:
+					_ = ledger.Record(job.item, amb.config.Storefront, StatusPurchased, "", "", nil)
+					successCount++
+				case err == ErrAlreadyOwned:
+					_ = ledger.Record(job.item, amb.config.Storefront, StatusSkippedOwned, "", "", nil)
+					skippedCount++
+				default:
+					worker.logger.WithError(err).WithField("item", job.item).Error("Failed to process item")
+					_ = ledger.Record(job.item, amb.config.Storefront, StatusFailed, "", "", err)
+					failCount++
+				}
+				cookiesDirty = true
+				mu.Unlock()
+
+				bar.Add(1)
+				remaining.Done()
+			}
+		}(w)
+	}
+
+	wg.Wait()
+
+	if cookiesDirty {
+		if err := amb.SaveCookies(); err != nil {
+			amb.logger.WithError(err).Warn("Failed to save cookies")
+		}
+	}
+
+	amb.logger.WithFields(logrus.Fields{
+		"success": successCount,
+		"failed":  failCount,
+		"skipped": skippedCount,
+		"retried": retryCount,
+		"total":   successCount + failCount + skippedCount,
+	}).Info("Finished processing CSV")
+
+	return nil
+}
+
+// applyCookiesTo copies the coordinator's cookies onto a worker's page so
+// every worker shares the same authenticated session without workers
+// touching the shared cookie file directly.
+func (amb *AmazonMusicBuyer) applyCookiesTo(page *rod.Page) {
+	for _, cookie := range amb.page.MustCookies() {
+		page.MustSetCookies(&proto.NetworkCookieParam{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			Expires:  cookie.Expires,
+			HTTPOnly: cookie.HTTPOnly,
+			Secure:   cookie.Secure,
+			SameSite: cookie.SameSite,
+		})
+	}
+}
+
+func throttled(page *rod.Page) bool {
+	for _, sel := range throttleSelectors {
+		if page.MustHas(sel) {
+			return true
+		}
+	}
+	return false
+}
+
+// backoffWithJitter returns the exponential backoff delay for the given
+// zero-based attempt number (base 2s, full jitter), capped at
+// backoffCap.
+func backoffWithJitter(attempt int) time.Duration {
+	delay := backoffBase * time.Duration(1<<uint(attempt))
+	if delay > backoffCap {
+		delay = backoffCap
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}